@@ -0,0 +1,412 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package generic provides the same self-balancing binary search tree as
+// the parent tree package, but built on type parameters instead of
+// interface{}. This avoids both the per-value interface allocation and the
+// type assertions a tree.Tree of interface{} forces on every comparison;
+// see BenchmarkInsert in tree_bench_test.go for the difference it makes
+// at scale. The interface{}-based tree.Tree is unaffected and keeps
+// working exactly as before for importers who are already using it.
+package generic
+
+// Ordered is satisfied by any type usable with the built-in operator <.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Node represents the internal nodes of a binary search tree.
+//
+// If a node is not nil, then it must store a value, and may contain links
+// to one or two subtrees (left and right). A node always has a pointer to
+// the parent node, unless it is the root node.
+type Node[T any] struct {
+	val T
+
+	parent *Node[T]
+	left   *Node[T]
+	right  *Node[T]
+
+	// height is the height of this node's subtree, maintained by the AVL
+	// rebalancing in Tree.Insert and Tree.removeNode. Unbalanced trees
+	// (see NewUnbalanced) leave it at the 1 a node is created with,
+	// since nothing ever reads it for them.
+	height int8
+
+	// lessFn is in each Node to allow us to do things with Nodes without
+	// knowing what Tree they are in.
+	lessFn func(a, b T) bool
+}
+
+// Val returns the value stored by the node.
+func (n *Node[T]) Val() T {
+	return n.val
+}
+
+// Next returns the next node after n, or nil if n is the last node.
+func (n *Node[T]) Next() *Node[T] {
+	if n.right != nil {
+		return n.right.Min()
+	}
+	p := n.parent
+	for p != nil && n == p.right {
+		n = p
+		p = p.parent
+	}
+	return p
+}
+
+// Prev returns the previous node before n, or nil if n is the first node.
+func (n *Node[T]) Prev() *Node[T] {
+	if n.left != nil {
+		return n.left.Max()
+	}
+	p := n.parent
+	for p != nil && n == p.left {
+		n = p
+		p = p.parent
+	}
+	return p
+}
+
+// Find searches the (sub)tree for the value v and returns the node if it is
+// found.
+func (t *Node[T]) Find(v T) *Node[T] {
+	for t != nil {
+		if t.lessFn(v, t.val) {
+			t = t.left
+		} else if t.lessFn(t.val, v) {
+			t = t.right
+		} else {
+			return t
+		}
+	}
+	return nil
+}
+
+// Contains searches the (sub)tree for the value v and returns true if it is
+// found.
+func (t *Node[T]) Contains(v T) bool {
+	return t.Find(v) != nil
+}
+
+// Max returns the node with the maximum value found in the (sub)tree,
+// or nil if the (sub)tree is empty.
+func (t *Node[T]) Max() *Node[T] {
+	if t == nil {
+		return nil
+	}
+	for t.right != nil {
+		t = t.right
+	}
+	return t
+}
+
+// Min returns the node with the minimum value found in the (sub)tree,
+// or nil if the (sub)tree is empty.
+func (t *Node[T]) Min() *Node[T] {
+	if t == nil {
+		return nil
+	}
+	for t.left != nil {
+		t = t.left
+	}
+	return t
+}
+
+// Height calculates the maximum height of the (sub)tree.
+func (t *Node[T]) Height() int {
+	if t == nil {
+		return 0
+	}
+	l, r := t.left.Height(), t.right.Height()
+	if l < r {
+		return 1 + r
+	}
+	return 1 + l
+}
+
+// nodeHeight returns the cached height of n, or 0 if n is nil.
+func nodeHeight[T any](n *Node[T]) int8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// maxInt8 returns the greater of two numbers a and b.
+func maxInt8(a, b int8) int8 {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+// balanceFactor returns the AVL balance factor of n, i.e. the height of its
+// left subtree minus the height of its right subtree.
+func balanceFactor[T any](n *Node[T]) int8 {
+	return nodeHeight(n.left) - nodeHeight(n.right)
+}
+
+// Tree represents a self-balancing binary search tree.
+//
+// The zero value of a Tree is not usable; use New, NewOrdered or
+// NewUnbalanced.
+type Tree[T any] struct {
+	root *Node[T]
+	size int
+
+	// balanced selects whether Insert and Delete keep the tree height
+	// bounded with AVL rotations. It is set by New and NewUnbalanced and
+	// never changes after that.
+	balanced bool
+
+	lessFn func(a, b T) bool
+}
+
+// New returns a new, self-balancing Tree, with lessFn as the function that
+// gives a < b.
+func New[T any](lessFn func(a, b T) bool) *Tree[T] {
+	return &Tree[T]{lessFn: lessFn, balanced: true}
+}
+
+// NewOrdered returns a new, self-balancing Tree of an Ordered type, using
+// the built-in < operator as its less function.
+func NewOrdered[T Ordered]() *Tree[T] {
+	return New(func(a, b T) bool { return a < b })
+}
+
+// NewUnbalanced returns a new Tree that never rebalances itself. Its
+// height can degrade to O(n) on sorted or otherwise adversarial input;
+// prefer New unless you have already randomized the insertion order
+// yourself.
+func NewUnbalanced[T any](lessFn func(a, b T) bool) *Tree[T] {
+	return &Tree[T]{lessFn: lessFn}
+}
+
+// Init initializes the tree with elements from vs in the given order.
+func (t *Tree[T]) Init(vs []T) {
+	for _, v := range vs {
+		t.Insert(v)
+	}
+}
+
+// Len returns the size of the tree.
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Root returns the root node of the tree, which is nil if the tree is empty.
+func (t *Tree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Slice returns the tree as a slice, in ascending order.
+func (t *Tree[T]) Slice() []T {
+	out := make([]T, 0, t.size)
+	for n := t.root.Min(); n != nil; n = n.Next() {
+		out = append(out, n.val)
+	}
+	return out
+}
+
+// Find searches the tree for the value v and returns the node if it is
+// found.
+func (t *Tree[T]) Find(v T) *Node[T] { return t.root.Find(v) }
+
+// Contains searches the tree for the value v and returns true if it is
+// found.
+func (t *Tree[T]) Contains(v T) bool { return t.root.Find(v) != nil }
+
+// Max returns the node with the maximum value in the tree.
+func (t *Tree[T]) Max() *Node[T] { return t.root.Max() }
+
+// Min returns the node with the minimum value in the tree.
+func (t *Tree[T]) Min() *Node[T] { return t.root.Min() }
+
+// Height calculates the maximum height of the tree.
+func (t *Tree[T]) Height() int { return t.root.Height() }
+
+// Insert inserts a value v into the tree if it does not exist and returns
+// the node containing it.
+//
+// Note: if the value v already is in the tree, nothing happens.
+func (t *Tree[T]) Insert(v T) *Node[T] {
+	var n *Node[T]
+	x := t.root
+	for x != nil {
+		n = x
+		if t.lessFn(v, x.val) {
+			x = x.left
+		} else if t.lessFn(x.val, v) {
+			x = x.right
+		} else {
+			return x
+		}
+	}
+
+	z := &Node[T]{val: v, parent: n, height: 1, lessFn: t.lessFn}
+	if n == nil {
+		t.root = z
+	} else if t.lessFn(v, n.val) {
+		n.left = z
+	} else {
+		n.right = z
+	}
+	t.size++
+	if t.balanced {
+		t.retrace(n, false)
+	}
+	return z
+}
+
+// Delete removes the value v from the tree, returning true if successful.
+func (t *Tree[T]) Delete(v T) bool {
+	if n := t.Find(v); n != nil {
+		t.removeNode(n)
+		return true
+	}
+	return false
+}
+
+// removeNode removes a node from the tree.
+// Note: we assume that n != nil!
+func (t *Tree[T]) removeNode(n *Node[T]) {
+	var start *Node[T]
+
+	if n.left == nil {
+		start = n.parent
+		t.transplant(n, n.right)
+	} else if n.right == nil {
+		start = n.parent
+		t.transplant(n, n.left)
+	} else {
+		s := n.right
+		for s.left != nil {
+			s = s.left
+		}
+		if s.parent != n {
+			start = s.parent
+			t.transplant(s, s.right)
+			s.right = n.right
+			s.right.parent = s
+		} else {
+			start = s
+		}
+		t.transplant(n, s)
+		s.left = n.left
+		s.left.parent = s
+	}
+	t.size--
+	if t.balanced {
+		t.retrace(start, true)
+	}
+}
+
+// transplant replaces u with v in the tree.
+// Note: we assume that u != nil!
+func (t *Tree[T]) transplant(u, v *Node[T]) {
+	if u.parent == nil {
+		t.root = v
+	} else if u == u.parent.left {
+		u.parent.left = v
+	} else {
+		u.parent.right = v
+	}
+	if v != nil {
+		v.parent = u.parent
+	}
+}
+
+// retrace walks from n up towards the root, recomputing heights and
+// performing AVL rotations wherever a node's balance factor leaves
+// [-1, 1].
+//
+// If full is false, retrace stops as soon as it reaches an ancestor whose
+// height was left unchanged, which is correct after Insert. If full is
+// true, retrace always continues to the root, which Delete requires.
+func (t *Tree[T]) retrace(n *Node[T], full bool) {
+	for n != nil {
+		old := n.height
+		n.height = 1 + maxInt8(nodeHeight(n.left), nodeHeight(n.right))
+		p := t.rebalance(n)
+		if !full && p.height == old {
+			return
+		}
+		n = p.parent
+	}
+}
+
+// rebalance restores the AVL property at n, if necessary, and returns the
+// node now occupying n's old position.
+func (t *Tree[T]) rebalance(n *Node[T]) *Node[T] {
+	switch bf := balanceFactor(n); {
+	case bf > 1:
+		if balanceFactor(n.left) < 0 {
+			t.rotateLeft(n.left)
+		}
+		return t.rotateRight(n)
+	case bf < -1:
+		if balanceFactor(n.right) > 0 {
+			t.rotateRight(n.right)
+		}
+		return t.rotateLeft(n)
+	default:
+		return n
+	}
+}
+
+// rotateLeft performs a left rotation around x, promoting x.right to take
+// x's place, and returns the new subtree root.
+func (t *Tree[T]) rotateLeft(x *Node[T]) *Node[T] {
+	y := x.right
+	x.right = y.left
+	if y.left != nil {
+		y.left.parent = x
+	}
+
+	y.parent = x.parent
+	if x.parent == nil {
+		t.root = y
+	} else if x == x.parent.left {
+		x.parent.left = y
+	} else {
+		x.parent.right = y
+	}
+
+	y.left = x
+	x.parent = y
+
+	x.height = 1 + maxInt8(nodeHeight(x.left), nodeHeight(x.right))
+	y.height = 1 + maxInt8(nodeHeight(y.left), nodeHeight(y.right))
+	return y
+}
+
+// rotateRight performs a right rotation around x, promoting x.left to take
+// x's place, and returns the new subtree root.
+func (t *Tree[T]) rotateRight(x *Node[T]) *Node[T] {
+	y := x.left
+	x.left = y.right
+	if y.right != nil {
+		y.right.parent = x
+	}
+
+	y.parent = x.parent
+	if x.parent == nil {
+		t.root = y
+	} else if x == x.parent.left {
+		x.parent.left = y
+	} else {
+		x.parent.right = y
+	}
+
+	y.right = x
+	x.parent = y
+
+	x.height = 1 + maxInt8(nodeHeight(x.left), nodeHeight(x.right))
+	y.height = 1 + maxInt8(nodeHeight(y.left), nodeHeight(y.right))
+	return y
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package generic
+
+import (
+	"math/rand"
+	"testing"
+
+	tree "github.com/goulash/tree"
+)
+
+// BenchmarkInsert compares inserting 1M random ints into a generic Tree[int]
+// against the interface{}-based tree.Tree, to put a number on the
+// allocations and type assertions the old API forces on every comparison.
+//
+// Run with: go test ./generic/... -bench Insert -benchmem
+func BenchmarkInsert(b *testing.B) {
+	const n = 1_000_000
+	vs := make([]int, n)
+	for i := range vs {
+		vs[i] = rand.Int()
+	}
+
+	b.Run("Generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			t := NewOrdered[int]()
+			for _, v := range vs {
+				t.Insert(v)
+			}
+		}
+	})
+
+	b.Run("Interface", func(b *testing.B) {
+		lessFn := func(a, b tree.Value) bool { return a.(int) < b.(int) }
+		for i := 0; i < b.N; i++ {
+			t := tree.New(lessFn)
+			for _, v := range vs {
+				t.Insert(v)
+			}
+		}
+	})
+}
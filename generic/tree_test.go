@@ -0,0 +1,94 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package generic
+
+import "testing"
+
+func TestTree(o *testing.T) {
+	tree := NewOrdered[int]()
+	tree.Init([]int{5, 2, 7, 3, 1, 6, 9, 4, 8, 2, 1, 8})
+
+	if siz := tree.Len(); siz != 9 {
+		o.Errorf("tree.Len() = %v; want 9", siz)
+	}
+	if max := tree.Max().Val(); max != 9 {
+		o.Errorf("tree.Max().Val() = %v; want 9", max)
+	}
+	if min := tree.Min().Val(); min != 1 {
+		o.Errorf("tree.Min().Val() = %v; want 1", min)
+	}
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if out := tree.Slice(); !equalSlices(out, want) {
+		o.Errorf("tree.Slice() = %v; want %v", out, want)
+	}
+	if height := tree.Height(); height > 4 {
+		o.Errorf("tree.Height() = %v; want <= 4", height)
+	}
+	for _, v := range want {
+		if !tree.Contains(v) {
+			o.Errorf("tree.Contains(%v) = false; want true", v)
+		}
+	}
+	for _, v := range []int{0, 10, -5} {
+		if tree.Contains(v) {
+			o.Errorf("tree.Contains(%v) = true; want false", v)
+		}
+	}
+}
+
+func TestTreeDelete(o *testing.T) {
+	tree := NewOrdered[int]()
+	tree.Init([]int{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	for i, v := range []int{6, 8, 3, 1, 5} {
+		if !tree.Delete(v) {
+			o.Errorf("tree.Delete(%v) = false; want true", v)
+		}
+		if tree.Delete(v) {
+			o.Errorf("tree.Delete(%v) = true; want false", v)
+		}
+		if siz := tree.Len(); siz != 9-(i+1) {
+			o.Errorf("tree.Len() = %v; want %v", siz, 9-(i+1))
+		}
+	}
+}
+
+func TestTreeUnbalanced(o *testing.T) {
+	tree := NewUnbalanced[int](func(a, b int) bool { return a < b })
+	tree.Init([]int{1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	if height := tree.Height(); height != 9 {
+		o.Errorf("tree.Height() = %v; want 9", height)
+	}
+}
+
+func TestNextPrev(o *testing.T) {
+	tree := NewOrdered[string]()
+	tree.Init([]string{"Lisa", "Lukas", "Ben", "Chris", "Chris", "Benni", "Sara", "Patrick"})
+
+	want := []string{"Ben", "Benni", "Chris", "Lisa", "Lukas", "Patrick", "Sara"}
+	n := tree.Min()
+	for _, v := range want {
+		if val := n.Val(); val != v {
+			o.Errorf("Node.Val() = %v; want %v", val, v)
+		}
+		n = n.Next()
+	}
+	if n != nil {
+		o.Errorf("Node = %v; want nil", n)
+	}
+}
+
+func equalSlices(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package tree
+
+// hintDepth bounds how many levels of a search a Hint remembers. An AVL
+// tree of even a billion elements has a height well under this, so once a
+// Hint has been warmed up by a previous call, levels beyond hintDepth are
+// the only ones that fall back to an unguided comparison.
+const hintDepth = 64
+
+// Hint remembers, for each depth of a previous FindHint, InsertHint or
+// DeleteHint call, which way the search went: left or right. Passing the
+// same Hint into repeated calls for nearby values - sequential inserts, or
+// a scan that keeps looking values up by key - lets each call confirm the
+// remembered direction with a single comparison instead of two, and only
+// falls back to comparing both ways where the path actually diverges.
+//
+// The zero value is a Hint with no guesses recorded yet, and is ready to
+// use. A Hint must not be shared between goroutines searching the same
+// Tree concurrently, but is otherwise tied to nothing but the values it
+// was last used with; it is safe to reuse across different Trees, though
+// it won't be useful unless they happen to branch the same way.
+type Hint [hintDepth]uint8
+
+const (
+	hintUnknown uint8 = 0
+	hintLeft    uint8 = 1
+	hintRight   uint8 = 2
+)
+
+// guess returns the direction h recorded at depth d, if any.
+func (h *Hint) guess(d int) (right, known bool) {
+	if h == nil || d >= len(h) {
+		return false, false
+	}
+	switch h[d] {
+	case hintLeft:
+		return false, true
+	case hintRight:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record stores the direction taken at depth d, so the next search through
+// this depth can try it first.
+func (h *Hint) record(d int, right bool) {
+	if h == nil || d >= len(h) {
+		return
+	}
+	if right {
+		h[d] = hintRight
+	} else {
+		h[d] = hintLeft
+	}
+}
+
+// direction compares v against nval and reports whether v belongs in the
+// right subtree, and whether it equals nval. If guessRight is known, it is
+// checked first, so confirming a correct guess costs one call to lessFn
+// instead of two.
+func direction(lessFn func(a, b Value) bool, nval, v Value, guessRight, known bool) (right, equal bool) {
+	if known && guessRight {
+		if lessFn(nval, v) {
+			return true, false
+		}
+		if lessFn(v, nval) {
+			return false, false
+		}
+		return false, true
+	}
+	if lessFn(v, nval) {
+		return false, false
+	}
+	if lessFn(nval, v) {
+		return true, false
+	}
+	return false, true
+}
+
+// FindHint is Find, but uses and updates h to speed up the search: at each
+// depth, it tries the direction h remembers from a previous call before
+// falling back to comparing both ways.
+func (t *Tree) FindHint(v Value, h *Hint) *Node {
+	n := t.root
+	for d := 0; n != nil; d++ {
+		guessRight, known := h.guess(d)
+		right, equal := direction(t.lessFn, n.val, v, guessRight, known)
+		if equal {
+			return n
+		}
+		h.record(d, right)
+		if right {
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return nil
+}
+
+// InsertHint is Insert, but uses and updates h to speed up the search for
+// where v belongs.
+func (t *Tree) InsertHint(v Value, h *Hint) *Node {
+	var n *Node
+	goRight := false
+	x := t.root
+	for d := 0; x != nil; d++ {
+		n = x
+		guessRight, known := h.guess(d)
+		right, equal := direction(t.lessFn, x.val, v, guessRight, known)
+		if equal {
+			return x
+		}
+		h.record(d, right)
+		goRight = right
+		if right {
+			x = x.right
+		} else {
+			x = x.left
+		}
+	}
+
+	z := &Node{val: v, parent: n, height: 1, lessFn: t.lessFn}
+	if n == nil {
+		// then the tree was empty => n = nil
+		t.root = z
+	} else if goRight {
+		n.right = z
+	} else {
+		n.left = z
+	}
+	t.size++
+	if t.augment != nil {
+		t.augment(z)
+	}
+	if t.balanced {
+		t.retrace(n, t.augment != nil)
+	}
+	return z
+}
+
+// DeleteHint is Delete, but uses and updates h to speed up the search for v.
+func (t *Tree) DeleteHint(v Value, h *Hint) bool {
+	if n := t.FindHint(v, h); n != nil {
+		t.removeNode(n)
+		return true
+	}
+	return false
+}
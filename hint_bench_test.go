@@ -0,0 +1,35 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package tree
+
+import "testing"
+
+// BenchmarkInsertSequential compares inserting monotonically increasing
+// keys via plain Insert against InsertHint with a single Hint shared
+// across the whole run. Ascending keys always descend the rightmost path,
+// so a shared Hint should confirm that path with one comparison per node
+// instead of two.
+func BenchmarkInsertSequential(b *testing.B) {
+	const n = 1_000_000
+
+	b.Run("Insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree := New(intLess)
+			for v := 0; v < n; v++ {
+				tree.Insert(v)
+			}
+		}
+	})
+
+	b.Run("InsertHint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree := New(intLess)
+			var h Hint
+			for v := 0; v < n; v++ {
+				tree.InsertHint(v, &h)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package tree
+
+import "testing"
+
+func TestFindHint(o *testing.T) {
+	tree := New(intLess)
+	tree.Init([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	var h Hint
+	for _, v := range []int{5, 2, 7, 3, 1, 6, 9, 4, 8} {
+		n := tree.FindHint(v, &h)
+		if n == nil {
+			o.Fatalf("tree.FindHint(%v) = nil; want a node", v)
+		}
+		if val := n.Val().(int); val != v {
+			o.Errorf("tree.FindHint(%v).Val() = %v; want %v", v, val, v)
+		}
+	}
+	for _, v := range []int{0, 10, -5} {
+		if n := tree.FindHint(v, &h); n != nil {
+			o.Errorf("tree.FindHint(%v) = %v; want nil", v, n)
+		}
+	}
+}
+
+func TestInsertHint(o *testing.T) {
+	tree := New(intLess)
+
+	var h Hint
+	for _, v := range []int{5, 2, 7, 3, 1, 6, 9, 4, 8} {
+		tree.InsertHint(v, &h)
+	}
+	if siz := tree.Len(); siz != 9 {
+		o.Errorf("tree.Len() = %v; want 9", siz)
+	}
+
+	want := []Value{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if out := tree.Slice(); !sliceEquals(out, want) {
+		o.Errorf("tree.Slice() = %v; want %v", out, want)
+	}
+
+	// Re-inserting an existing value with a hint must not grow the tree.
+	if n := tree.InsertHint(5, &h); n.Val().(int) != 5 {
+		o.Errorf("tree.InsertHint(5) = %v; want node with value 5", n.Val())
+	}
+	if siz := tree.Len(); siz != 9 {
+		o.Errorf("tree.Len() = %v after re-insert; want 9", siz)
+	}
+}
+
+func TestDeleteHint(o *testing.T) {
+	tree := New(intLess)
+	tree.Init([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	var h Hint
+	for _, v := range []int{6, 8, 3, 1, 5} {
+		if !tree.DeleteHint(v, &h) {
+			o.Errorf("tree.DeleteHint(%v) = false; want true", v)
+		}
+		if tree.DeleteHint(v, &h) {
+			o.Errorf("tree.DeleteHint(%v) = true; want false", v)
+		}
+	}
+	if siz := tree.Len(); siz != 4 {
+		o.Errorf("tree.Len() = %v; want 4", siz)
+	}
+
+	want := []Value{2, 4, 7, 9}
+	if out := tree.Slice(); !sliceEquals(out, want) {
+		o.Errorf("tree.Slice() = %v; want %v", out, want)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package tree
+
+// IntervalTree is a Tree whose values each carry a [min, max] range, kept
+// ordered by min. Besides everything a Tree can do, it answers overlap
+// queries - "which intervals touch [min, max]?" - in O(log n + k) instead
+// of the linear scan a plain Tree forces, by caching the maximum max seen
+// in each subtree (maxEnd) and using it to prune subtrees that can't
+// possibly contain a match.
+//
+// IntervalTree embeds *Tree, so Insert, Delete, Find, Len and the rest of
+// Tree's API are all available directly; maxEnd is kept up to date
+// automatically as those methods run, including across AVL rotations.
+type IntervalTree struct {
+	*Tree
+
+	// lessMin orders two endpoints - either two min's, two max's, or one
+	// of each - from the values' shared range domain.
+	lessMin func(a, b Value) bool
+
+	// getRange extracts the [min, max] range carried by a value.
+	getRange func(v Value) (min, max Value)
+}
+
+// NewInterval returns a new, empty IntervalTree. lessMin orders two
+// endpoints taken from the range domain getRange extracts its values'
+// [min, max] from.
+func NewInterval(lessMin func(a, b Value) bool, getRange func(v Value) (min, max Value)) *IntervalTree {
+	it := &IntervalTree{lessMin: lessMin, getRange: getRange}
+	it.Tree = New(func(a, b Value) bool {
+		aMin, _ := getRange(a)
+		bMin, _ := getRange(b)
+		return lessMin(aMin, bMin)
+	})
+	it.Tree.augment = it.updateMaxEnd
+	return it
+}
+
+// updateMaxEnd recomputes n's cached maxEnd from its own range and its
+// children's already-current maxEnd. It is installed as it.Tree.augment, so
+// Tree calls it for us whenever n's children may have changed.
+func (it *IntervalTree) updateMaxEnd(n *Node) {
+	_, maxEnd := it.getRange(n.val)
+	if n.left != nil && it.lessMin(maxEnd, n.left.aux) {
+		maxEnd = n.left.aux
+	}
+	if n.right != nil && it.lessMin(maxEnd, n.right.aux) {
+		maxEnd = n.right.aux
+	}
+	n.aux = maxEnd
+}
+
+// Overlaps returns every value in the tree whose range touches [min, max],
+// in ascending order of min.
+func (it *IntervalTree) Overlaps(min, max Value) []Value {
+	var out []Value
+	it.OverlapsFn(min, max, func(v Value) bool {
+		out = append(out, v)
+		return true
+	})
+	return out
+}
+
+// OverlapsFn calls fn for every value in the tree whose range touches
+// [min, max], in ascending order of min, until fn returns false.
+func (it *IntervalTree) OverlapsFn(min, max Value, fn func(Value) bool) {
+	overlaps(it.root, min, max, it.lessMin, it.getRange, fn)
+}
+
+// overlaps walks n looking for ranges that touch [qmin, qmax], pruning any
+// subtree whose cached maxEnd falls short of qmin, and reports whether the
+// walk should continue.
+func overlaps(n *Node, qmin, qmax Value, lessMin func(a, b Value) bool, getRange func(Value) (min, max Value), fn func(Value) bool) bool {
+	if n == nil {
+		return true
+	}
+	if lessMin(n.aux, qmin) {
+		// Nothing in this subtree ends at or after qmin.
+		return true
+	}
+	if !overlaps(n.left, qmin, qmax, lessMin, getRange, fn) {
+		return false
+	}
+
+	vmin, vmax := getRange(n.val)
+	if !lessMin(qmax, vmin) && !lessMin(vmax, qmin) {
+		if !fn(n.val) {
+			return false
+		}
+	}
+	if lessMin(qmax, vmin) {
+		// n's min is already past qmax, and every node to its right has
+		// an even larger min, so none of them can overlap either.
+		return true
+	}
+	return overlaps(n.right, qmin, qmax, lessMin, getRange, fn)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// This file tests the IntervalTree.
+
+package tree
+
+import (
+	"sort"
+	"testing"
+)
+
+type span struct {
+	min, max int
+	name     string
+}
+
+func newSpanTree() *IntervalTree {
+	return NewInterval(
+		func(a, b Value) bool { return a.(int) < b.(int) },
+		func(v Value) (min, max Value) {
+			s := v.(span)
+			return s.min, s.max
+		},
+	)
+}
+
+func TestIntervalTreeOverlaps(o *testing.T) {
+	it := newSpanTree()
+	spans := []span{
+		{0, 5, "a"},
+		{10, 15, "b"},
+		{6, 9, "c"},
+		{12, 20, "d"},
+		{1, 2, "e"},
+		{100, 200, "f"},
+	}
+	for _, s := range spans {
+		it.Insert(s)
+	}
+
+	if siz := it.Len(); siz != len(spans) {
+		o.Errorf("it.Len() = %v; want %v", siz, len(spans))
+	}
+
+	names := func(out []Value) []string {
+		var ns []string
+		for _, v := range out {
+			ns = append(ns, v.(span).name)
+		}
+		sort.Strings(ns)
+		return ns
+	}
+
+	if out := names(it.Overlaps(11, 13)); !equalStrings(out, []string{"b", "d"}) {
+		o.Errorf("it.Overlaps(11, 13) = %v; want [b d]", out)
+	}
+	if out := names(it.Overlaps(3, 7)); !equalStrings(out, []string{"a", "c"}) {
+		o.Errorf("it.Overlaps(3, 7) = %v; want [a c]", out)
+	}
+	if out := names(it.Overlaps(50, 60)); len(out) != 0 {
+		o.Errorf("it.Overlaps(50, 60) = %v; want []", out)
+	}
+	if out := names(it.Overlaps(0, 1000)); !equalStrings(out, []string{"a", "b", "c", "d", "e", "f"}) {
+		o.Errorf("it.Overlaps(0, 1000) = %v; want everything", out)
+	}
+}
+
+func TestIntervalTreeDeleteMaintainsMaxEnd(o *testing.T) {
+	it := newSpanTree()
+	it.Insert(span{0, 100, "big"})
+	it.Insert(span{10, 20, "small"})
+
+	it.Delete(span{0, 100, "big"})
+
+	if out := it.Overlaps(50, 60); len(out) != 0 {
+		o.Errorf("it.Overlaps(50, 60) after deleting the wide span = %v; want []", out)
+	}
+	if out := it.Overlaps(15, 15); len(out) != 1 {
+		o.Errorf("it.Overlaps(15, 15) = %v; want [small]", out)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package tree
+
+// PNode represents the internal nodes of a PTree.
+//
+// Unlike Node, a PNode never changes once created: With and Without build
+// new PNodes for the path they modify and reuse every subtree that didn't
+// change, so a PNode may be shared by several PTree versions at once. This
+// rules out parent pointers, since a shared node cannot point to a single
+// parent; walk a PTree with an Iterator instead of Node.Next/Node.Prev.
+type PNode struct {
+	val Value
+
+	left  *PNode
+	right *PNode
+
+	height int8
+}
+
+// Val returns the value stored by the node.
+func (n *PNode) Val() Value {
+	return n.val
+}
+
+// PTree represents a persistent (applicative) binary search tree.
+//
+// With and Without never modify the receiver; they return a new *PTree
+// that shares every subtree it didn't have to change with the receiver.
+// This makes old versions of a PTree safe to keep around and to read from
+// other goroutines even while newer versions are being derived from it.
+//
+// The zero value of a PTree is not usable; use NewPTree.
+type PTree struct {
+	root *PNode
+	size int
+
+	lessFn func(a, b Value) bool
+}
+
+// NewPTree returns a new, empty PTree, with lessFn as the function that
+// gives a < b.
+func NewPTree(lessFn func(a, b Value) bool) *PTree {
+	return &PTree{lessFn: lessFn}
+}
+
+// Len returns the number of elements in the tree.
+func (t *PTree) Len() int {
+	return t.size
+}
+
+// Root returns the root node of the tree, which is nil if the tree is empty.
+func (t *PTree) Root() *PNode {
+	return t.root
+}
+
+// With returns a new PTree containing v in addition to everything in t.
+//
+// Note: if v is already in t, With returns t itself, since there is
+// nothing to add.
+func (t *PTree) With(v Value) *PTree {
+	root, added := pinsert(t.root, v, t.lessFn)
+	if !added {
+		return t
+	}
+	return &PTree{root: root, size: t.size + 1, lessFn: t.lessFn}
+}
+
+// Without returns a new PTree containing everything in t except v.
+//
+// Note: if v is not in t, Without returns t itself, since there is nothing
+// to remove.
+func (t *PTree) Without(v Value) *PTree {
+	root, removed := premove(t.root, v, t.lessFn)
+	if !removed {
+		return t
+	}
+	return &PTree{root: root, size: t.size - 1, lessFn: t.lessFn}
+}
+
+// pheight returns the cached height of n, or 0 if n is nil.
+func pheight(n *PNode) int8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// pbalance restores the AVL property at n, which must have freshly
+// recomputed children but a stale height, and returns the node that now
+// occupies n's place. n is always a node built by this version's With or
+// Without, so it is safe to mutate in place; its children may still be
+// shared with older versions and are never mutated.
+func pbalance(n *PNode) *PNode {
+	n.height = 1 + maxInt8(pheight(n.left), pheight(n.right))
+
+	switch bf := pheight(n.left) - pheight(n.right); {
+	case bf > 1:
+		if pheight(n.left.left) < pheight(n.left.right) {
+			n.left = protateLeft(n.left)
+		}
+		return protateRight(n)
+	case bf < -1:
+		if pheight(n.right.right) < pheight(n.right.left) {
+			n.right = protateRight(n.right)
+		}
+		return protateLeft(n)
+	default:
+		return n
+	}
+}
+
+// protateLeft returns the result of a left rotation around x, building new
+// nodes for x and its right child y while reusing their subtrees.
+func protateLeft(x *PNode) *PNode {
+	y := x.right
+	nx := &PNode{val: x.val, left: x.left, right: y.left}
+	nx.height = 1 + maxInt8(pheight(nx.left), pheight(nx.right))
+	ny := &PNode{val: y.val, left: nx, right: y.right}
+	ny.height = 1 + maxInt8(pheight(ny.left), pheight(ny.right))
+	return ny
+}
+
+// protateRight returns the result of a right rotation around x, building
+// new nodes for x and its left child y while reusing their subtrees.
+func protateRight(x *PNode) *PNode {
+	y := x.left
+	nx := &PNode{val: x.val, left: y.right, right: x.right}
+	nx.height = 1 + maxInt8(pheight(nx.left), pheight(nx.right))
+	ny := &PNode{val: y.val, left: y.left, right: nx}
+	ny.height = 1 + maxInt8(pheight(ny.left), pheight(ny.right))
+	return ny
+}
+
+// pinsert returns a tree containing v in addition to everything in n,
+// reusing every subtree of n that isn't on the path to v. The returned bool
+// reports whether v was actually added, i.e. whether it wasn't already there.
+func pinsert(n *PNode, v Value, lessFn func(a, b Value) bool) (*PNode, bool) {
+	if n == nil {
+		return &PNode{val: v, height: 1}, true
+	}
+
+	if lessFn(v, n.val) {
+		left, added := pinsert(n.left, v, lessFn)
+		if !added {
+			return n, false
+		}
+		return pbalance(&PNode{val: n.val, left: left, right: n.right}), true
+	} else if lessFn(n.val, v) {
+		right, added := pinsert(n.right, v, lessFn)
+		if !added {
+			return n, false
+		}
+		return pbalance(&PNode{val: n.val, left: n.left, right: right}), true
+	}
+	return n, false
+}
+
+// premove returns a tree containing everything in n except v, reusing every
+// subtree of n that isn't on the path to v. The returned bool reports
+// whether v was actually found and removed.
+func premove(n *PNode, v Value, lessFn func(a, b Value) bool) (*PNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	if lessFn(v, n.val) {
+		left, removed := premove(n.left, v, lessFn)
+		if !removed {
+			return n, false
+		}
+		return pbalance(&PNode{val: n.val, left: left, right: n.right}), true
+	} else if lessFn(n.val, v) {
+		right, removed := premove(n.right, v, lessFn)
+		if !removed {
+			return n, false
+		}
+		return pbalance(&PNode{val: n.val, left: n.left, right: right}), true
+	}
+
+	// n.val == v: n itself must go.
+	switch {
+	case n.left == nil:
+		return n.right, true
+	case n.right == nil:
+		return n.left, true
+	default:
+		s := n.right
+		for s.left != nil {
+			s = s.left
+		}
+		right, _ := premove(n.right, s.val, lessFn)
+		return pbalance(&PNode{val: s.val, left: n.left, right: right}), true
+	}
+}
+
+// Iterator walks a PTree in either direction without needing parent
+// pointers, by keeping the path from the root to the current node on an
+// explicit stack.
+//
+// The zero value is not usable; get one from PTree.Iterator. A PTree
+// produced by With or Without after the Iterator was created does not
+// affect the Iterator, since it walks the PNodes it started with.
+type Iterator struct {
+	root   *PNode
+	lessFn func(a, b Value) bool
+
+	path  []*PNode
+	began bool
+}
+
+// Iterator returns an Iterator positioned before the first element of t.
+func (t *PTree) Iterator() *Iterator {
+	return &Iterator{root: t.root, lessFn: t.lessFn}
+}
+
+// Next advances the iterator to the next element in ascending order and
+// returns it, or returns false if there is none.
+func (it *Iterator) Next() (Value, bool) {
+	switch {
+	case !it.began:
+		it.began = true
+		it.pushMinPath(it.root)
+	case len(it.path) == 0:
+		return nil, false
+	default:
+		if cur := it.path[len(it.path)-1]; cur.right != nil {
+			it.pushMinPath(cur.right)
+		} else {
+			it.ascend(true)
+		}
+	}
+	if len(it.path) == 0 {
+		return nil, false
+	}
+	return it.path[len(it.path)-1].val, true
+}
+
+// Prev moves the iterator to the previous element in ascending order and
+// returns it, or returns false if there is none.
+func (it *Iterator) Prev() (Value, bool) {
+	switch {
+	case !it.began:
+		it.began = true
+		it.pushMaxPath(it.root)
+	case len(it.path) == 0:
+		return nil, false
+	default:
+		if cur := it.path[len(it.path)-1]; cur.left != nil {
+			it.pushMaxPath(cur.left)
+		} else {
+			it.ascend(false)
+		}
+	}
+	if len(it.path) == 0 {
+		return nil, false
+	}
+	return it.path[len(it.path)-1].val, true
+}
+
+// pushMinPath descends from n to its minimum, pushing every node visited.
+func (it *Iterator) pushMinPath(n *PNode) {
+	for n != nil {
+		it.path = append(it.path, n)
+		n = n.left
+	}
+}
+
+// pushMaxPath descends from n to its maximum, pushing every node visited.
+func (it *Iterator) pushMaxPath(n *PNode) {
+	for n != nil {
+		it.path = append(it.path, n)
+		n = n.right
+	}
+}
+
+// ascend pops the current node and then its ancestors, stopping as soon as
+// it finds one on the correct side of the node just popped: greater, to
+// continue ascending order (fwd), or smaller, to continue descending order.
+func (it *Iterator) ascend(fwd bool) {
+	for len(it.path) > 0 {
+		v := it.path[len(it.path)-1].val
+		it.path = it.path[:len(it.path)-1]
+		if len(it.path) == 0 {
+			return
+		}
+		top := it.path[len(it.path)-1].val
+		if fwd && it.lessFn(v, top) {
+			return
+		}
+		if !fwd && it.lessFn(top, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2013, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// This file tests the PTree persistent tree.
+
+package tree
+
+import "testing"
+
+func intLess(a, b Value) bool { return a.(int) < b.(int) }
+
+func ptreeSlice(t *PTree) []Value {
+	out := make([]Value, 0, t.Len())
+	it := t.Iterator()
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestPTreeWith(o *testing.T) {
+	t0 := NewPTree(intLess)
+	t1 := t0.With(5)
+	t2 := t1.With(2).With(7).With(3).With(1).With(6).With(9).With(4).With(8)
+
+	if siz := t0.Len(); siz != 0 {
+		o.Errorf("t0.Len() = %v; want 0", siz)
+	}
+	if siz := t1.Len(); siz != 1 {
+		o.Errorf("t1.Len() = %v; want 1", siz)
+	}
+	if siz := t2.Len(); siz != 9 {
+		o.Errorf("t2.Len() = %v; want 9", siz)
+	}
+
+	want := []Value{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if out := ptreeSlice(t2); !sliceEquals(out, want) {
+		o.Errorf("ptreeSlice(t2) = %v; want %v", out, want)
+	}
+
+	// Re-adding a value already present must return the same tree.
+	if again := t2.With(4); again != t2 {
+		o.Errorf("t2.With(4) built a new tree; want the same *PTree back")
+	}
+}
+
+func TestPTreeWithout(o *testing.T) {
+	t0 := NewPTree(intLess)
+	for _, v := range []Value{5, 2, 7, 3, 1, 6, 9, 4, 8} {
+		t0 = t0.With(v)
+	}
+
+	t1 := t0.Without(2).Without(9).Without(5)
+	if siz := t1.Len(); siz != 6 {
+		o.Errorf("t1.Len() = %v; want 6", siz)
+	}
+	want := []Value{1, 3, 4, 6, 7, 8}
+	if out := ptreeSlice(t1); !sliceEquals(out, want) {
+		o.Errorf("ptreeSlice(t1) = %v; want %v", out, want)
+	}
+
+	// Removing a value that isn't there must return the same tree.
+	if again := t1.Without(100); again != t1 {
+		o.Errorf("t1.Without(100) built a new tree; want the same *PTree back")
+	}
+}
+
+// TestPTreeStructuralSharing confirms that old versions of a PTree keep
+// traversing their original contents even after many further mutations,
+// which is the entire point of a persistent tree.
+func TestPTreeStructuralSharing(o *testing.T) {
+	versions := make([]*PTree, 0, 10)
+	t := NewPTree(intLess)
+	versions = append(versions, t)
+	for _, v := range []Value{5, 2, 7, 3, 1, 6, 9, 4, 8} {
+		t = t.With(v)
+		versions = append(versions, t)
+	}
+	for _, v := range []Value{2, 4, 1, 3, 9, 8} {
+		t = t.Without(v)
+		versions = append(versions, t)
+	}
+
+	wantSiz := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 8, 7, 6, 5, 4, 3}
+	for i, v := range versions {
+		if siz := v.Len(); siz != wantSiz[i] {
+			o.Errorf("versions[%d].Len() = %v; want %v", i, siz, wantSiz[i])
+		}
+	}
+
+	// The fully-populated version must still contain everything,
+	// regardless of all the Withouts applied afterwards.
+	full := versions[9]
+	want := []Value{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if out := ptreeSlice(full); !sliceEquals(out, want) {
+		o.Errorf("ptreeSlice(full) = %v; want %v", out, want)
+	}
+
+	// And the very last version reflects every mutation made to it.
+	last := versions[len(versions)-1]
+	wantLast := []Value{5, 6, 7}
+	if out := ptreeSlice(last); !sliceEquals(out, wantLast) {
+		o.Errorf("ptreeSlice(last) = %v; want %v", out, wantLast)
+	}
+}
+
+func TestPTreeIteratorPrev(o *testing.T) {
+	t := NewPTree(intLess)
+	for _, v := range []Value{5, 2, 7, 3, 1, 6, 9, 4, 8} {
+		t = t.With(v)
+	}
+
+	it := t.Iterator()
+	var fwd []Value
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		fwd = append(fwd, v)
+	}
+
+	it = t.Iterator()
+	var bwd []Value
+	for v, ok := it.Prev(); ok; v, ok = it.Prev() {
+		bwd = append(bwd, v)
+	}
+
+	for i, v := range fwd {
+		if want := bwd[len(bwd)-1-i]; v != want {
+			o.Errorf("forward[%d] = %v; backward[%d] = %v; want equal", i, v, len(bwd)-1-i, want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ package tree
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math/rand"
 )
 
@@ -27,6 +28,17 @@ type Node struct {
 	left   *Node
 	right  *Node
 
+	// height is the height of this node's subtree, maintained by the AVL
+	// rebalancing in Tree.Insert and Tree.removeNode. Unbalanced trees
+	// (see NewUnbalanced) leave it at the 1 a node is created with,
+	// since nothing ever reads it for them.
+	height int8
+
+	// aux holds whatever a Tree's augment callback wants to cache about
+	// this node's subtree, e.g. the maxEnd used by IntervalTree. It is
+	// nil unless the owning Tree sets an augment function.
+	aux Value
+
 	// lessFn is in each Node to allow us to do things with Nodes without
 	// knowing what Tree they are in.
 	lessFn func(a, b Value) bool
@@ -37,6 +49,12 @@ func (n *Node) Val() Value {
 	return n.val
 }
 
+// Aux returns whatever the owning Tree's augment callback last cached for
+// this node, or nil if the Tree has none.
+func (n *Node) Aux() Value {
+	return n.aux
+}
+
 // Next returns the next node after n, or nil if n is the last node.
 func (n *Node) Next() *Node {
 	if n.right != nil {
@@ -156,6 +174,28 @@ func maxInt(a, b int) int {
 	return a
 }
 
+// nodeHeight returns the cached height of n, or 0 if n is nil.
+func nodeHeight(n *Node) int8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// maxInt8 returns the greater of two numbers a and b.
+func maxInt8(a, b int8) int8 {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+// balanceFactor returns the AVL balance factor of n, i.e. the height of its
+// left subtree minus the height of its right subtree.
+func balanceFactor(n *Node) int8 {
+	return nodeHeight(n.left) - nodeHeight(n.right)
+}
+
 // Tree represents a binary search tree.
 //
 // The zero value of a Tree is a ready to use tree. Do note however, that
@@ -164,14 +204,41 @@ type Tree struct {
 	root *Node
 	size int
 
+	// balanced selects whether Insert and Delete keep the tree height
+	// bounded with AVL rotations. It is set by New and NewUnbalanced and
+	// never changes after that.
+	balanced bool
+
+	// augment, if set, is called with every node whose set of children
+	// may have just changed - after a height update, and after each
+	// rotation - so that it can recompute and cache derived data about
+	// the node's subtree in Node.aux. IntervalTree uses this to maintain
+	// maxEnd. It is nil for a plain Tree.
+	augment func(n *Node)
+
 	lessFn func(a, b Value) bool
 }
 
 // New returns a new Tree to use, with lessFn as the function that gives a < b.
 //
-// A Tree must be created with this function, otherwise trying to insert into
-// it will cause a panic.
+// The tree returned is self-balancing: Insert and Delete perform AVL
+// rotations to keep the height within ~1.44*log2(n), so Find stays
+// logarithmic regardless of insertion order. Use NewUnbalanced if you
+// already know your insertions are randomized and don't want to pay for
+// rotations that won't be needed.
+//
+// A Tree must be created with this function or NewUnbalanced, otherwise
+// trying to insert into it will cause a panic.
 func New(lessFn func(a, b Value) bool) *Tree {
+	return &Tree{lessFn: lessFn, balanced: true}
+}
+
+// NewUnbalanced returns a new Tree that never rebalances itself, i.e. the
+// plain, unbalanced binary search tree this package provided before AVL
+// rotations were added. Its height can degrade to O(n) on sorted or
+// otherwise adversarial input; prefer New unless you have already
+// randomized the insertion order yourself.
+func NewUnbalanced(lessFn func(a, b Value) bool) *Tree {
 	return &Tree{lessFn: lessFn}
 }
 
@@ -252,11 +319,132 @@ func (t *Tree) Min() *Node { return t.root.Min() }
 
 func (t *Tree) Height() int { return t.root.Height() }
 
-// Range returns the search range [from, to] as a slice.
-//func (t *Tree) Range(from, to Value) []Value {
-//	return nil
-//	// TODO
-//}
+// Range returns every value v in the tree with from <= v <= to, in
+// ascending order.
+func (t *Tree) Range(from, to Value) []Value {
+	out := []Value{}
+	t.AscendRange(from, to, func(v Value) bool {
+		out = append(out, v)
+		return true
+	})
+	return out
+}
+
+// AscendGreaterOrEqual calls fn for every value v in the tree with
+// v >= pivot, in ascending order, until fn returns false.
+func (t *Tree) AscendGreaterOrEqual(pivot Value, fn func(Value) bool) {
+	ascendGE(t.root, pivot, t.lessFn, fn)
+}
+
+// DescendLessOrEqual calls fn for every value v in the tree with
+// v <= pivot, in descending order, until fn returns false.
+func (t *Tree) DescendLessOrEqual(pivot Value, fn func(Value) bool) {
+	descendLE(t.root, pivot, t.lessFn, fn)
+}
+
+// AscendRange calls fn for every value v in the tree with from <= v <= to,
+// in ascending order, until fn returns false.
+func (t *Tree) AscendRange(from, to Value, fn func(Value) bool) {
+	ascendRange(t.root, from, to, t.lessFn, fn)
+}
+
+// DescendRange calls fn for every value v in the tree with from <= v <= to,
+// in descending order, until fn returns false.
+func (t *Tree) DescendRange(from, to Value, fn func(Value) bool) {
+	descendRange(t.root, from, to, t.lessFn, fn)
+}
+
+// ascendGE walks n in ascending order, pruning subtrees that cannot contain
+// a value >= pivot, and reports whether the walk should continue.
+func ascendGE(n *Node, pivot Value, lessFn func(a, b Value) bool, fn func(Value) bool) bool {
+	if n == nil {
+		return true
+	}
+	if lessFn(n.val, pivot) {
+		// n.val is out of range, and so is everything in n.left.
+		return ascendGE(n.right, pivot, lessFn, fn)
+	}
+	if !ascendGE(n.left, pivot, lessFn, fn) {
+		return false
+	}
+	if !fn(n.val) {
+		return false
+	}
+	return ascendGE(n.right, pivot, lessFn, fn)
+}
+
+// descendLE walks n in descending order, pruning subtrees that cannot
+// contain a value <= pivot, and reports whether the walk should continue.
+func descendLE(n *Node, pivot Value, lessFn func(a, b Value) bool, fn func(Value) bool) bool {
+	if n == nil {
+		return true
+	}
+	if lessFn(pivot, n.val) {
+		// n.val is out of range, and so is everything in n.right.
+		return descendLE(n.left, pivot, lessFn, fn)
+	}
+	if !descendLE(n.right, pivot, lessFn, fn) {
+		return false
+	}
+	if !fn(n.val) {
+		return false
+	}
+	return descendLE(n.left, pivot, lessFn, fn)
+}
+
+// ascendRange walks n in ascending order, pruning subtrees that fall
+// outside [from, to], and reports whether the walk should continue.
+func ascendRange(n *Node, from, to Value, lessFn func(a, b Value) bool, fn func(Value) bool) bool {
+	if n == nil {
+		return true
+	}
+	if lessFn(n.val, from) {
+		return ascendRange(n.right, from, to, lessFn, fn)
+	}
+	if lessFn(to, n.val) {
+		return ascendRange(n.left, from, to, lessFn, fn)
+	}
+	if !ascendRange(n.left, from, to, lessFn, fn) {
+		return false
+	}
+	if !fn(n.val) {
+		return false
+	}
+	return ascendRange(n.right, from, to, lessFn, fn)
+}
+
+// descendRange walks n in descending order, pruning subtrees that fall
+// outside [from, to], and reports whether the walk should continue.
+func descendRange(n *Node, from, to Value, lessFn func(a, b Value) bool, fn func(Value) bool) bool {
+	if n == nil {
+		return true
+	}
+	if lessFn(n.val, from) {
+		return descendRange(n.right, from, to, lessFn, fn)
+	}
+	if lessFn(to, n.val) {
+		return descendRange(n.left, from, to, lessFn, fn)
+	}
+	if !descendRange(n.right, from, to, lessFn, fn) {
+		return false
+	}
+	if !fn(n.val) {
+		return false
+	}
+	return descendRange(n.left, from, to, lessFn, fn)
+}
+
+// AscendFrom calls fn for n and then every node after it, in ascending
+// order, until fn returns false or the nodes run out. It is a shortcut for
+// callers that already hold a starting Node, built on top of Next.
+func (n *Node) AscendFrom(fn func(Value) bool) {
+	for n != nil {
+		if !fn(n.val) {
+			return
+		}
+		n = n.Next()
+	}
+}
 
 // Insert inserts a value v into the tree if it does not exist and returns the
 // node containing it.
@@ -277,7 +465,7 @@ func (t *Tree) Insert(v Value) *Node {
 		}
 	}
 
-	z := &Node{v, n, nil, nil, t.lessFn}
+	z := &Node{val: v, parent: n, height: 1, lessFn: t.lessFn}
 	if n == nil {
 		// then the tree was empty => n = nil
 		t.root = z
@@ -287,6 +475,15 @@ func (t *Tree) Insert(v Value) *Node {
 		n.right = z
 	}
 	t.size++
+	if t.augment != nil {
+		t.augment(z)
+	}
+	if t.balanced {
+		// An augment function (see IntervalTree) can change an ancestor's
+		// cached aux even when its height doesn't, so we can only take
+		// the early-stop shortcut when there is none to worry about.
+		t.retrace(n, t.augment != nil)
+	}
 	return z
 }
 
@@ -302,9 +499,16 @@ func (t *Tree) Delete(v Value) bool {
 // removeNode removes a node from the tree.
 // Note: we assume that n != nil!
 func (t *Tree) removeNode(n *Node) {
+	// start is the lowest node whose subtree actually lost a child, i.e.
+	// the point from which height needs to be recomputed on the way up
+	// to the root.
+	var start *Node
+
 	if n.left == nil {
+		start = n.parent
 		t.transplant(n, n.right)
 	} else if n.right == nil {
+		start = n.parent
 		t.transplant(n, n.left)
 	} else {
 		// successor of n
@@ -313,15 +517,24 @@ func (t *Tree) removeNode(n *Node) {
 			s = s.left
 		}
 		if s.parent != n {
+			start = s.parent
 			t.transplant(s, s.right)
 			s.right = n.right
 			s.right.parent = s
+		} else {
+			start = s
 		}
 		t.transplant(n, s)
 		s.left = n.left
 		s.left.parent = s
 	}
 	t.size--
+	if t.balanced {
+		// Unlike Insert, Delete must retrace all the way to the root:
+		// a rotation can leave a subtree's height unchanged yet still
+		// shrink the height of an ancestor further up.
+		t.retrace(start, true)
+	}
 }
 
 // transplant replaces n with m in the tree.
@@ -338,3 +551,229 @@ func (t *Tree) transplant(u, v *Node) {
 		v.parent = u.parent
 	}
 }
+
+// retrace walks from n up towards the root, recomputing heights and
+// performing AVL rotations wherever a node's balance factor leaves
+// [-1, 1]. If the tree has an augment function, it is also re-run on every
+// node retrace visits, since a rotation already refreshes the nodes it
+// touches directly (see rotateLeft/rotateRight) but a plain height update
+// does not.
+//
+// If full is false, retrace stops as soon as it reaches an ancestor whose
+// height was left unchanged, which is correct after Insert: a single
+// rotation (or no rotation at all) there means nothing above it can have
+// changed either. If full is true, retrace always continues to the root,
+// which Delete requires, since removing a node can shrink the height of
+// an ancestor even though the node directly above the deletion rebalanced
+// without a height change; an augmented Tree's Insert also needs full,
+// since aux can change even when height doesn't.
+func (t *Tree) retrace(n *Node, full bool) {
+	for n != nil {
+		old := n.height
+		n.height = 1 + maxInt8(nodeHeight(n.left), nodeHeight(n.right))
+		p := t.rebalance(n)
+		if t.augment != nil {
+			t.augment(p)
+		}
+		if !full && p.height == old {
+			return
+		}
+		n = p.parent
+	}
+}
+
+// rebalance restores the AVL property at n, if necessary, and returns the
+// node now occupying n's old position (n itself, unless a rotation made
+// one of its children the new subtree root).
+func (t *Tree) rebalance(n *Node) *Node {
+	switch bf := balanceFactor(n); {
+	case bf > 1:
+		if balanceFactor(n.left) < 0 {
+			t.rotateLeft(n.left)
+		}
+		return t.rotateRight(n)
+	case bf < -1:
+		if balanceFactor(n.right) > 0 {
+			t.rotateRight(n.right)
+		}
+		return t.rotateLeft(n)
+	default:
+		return n
+	}
+}
+
+// rotateLeft performs a left rotation around x, promoting x.right to take
+// x's place, and returns the new subtree root.
+func (t *Tree) rotateLeft(x *Node) *Node {
+	y := x.right
+	x.right = y.left
+	if y.left != nil {
+		y.left.parent = x
+	}
+
+	y.parent = x.parent
+	if x.parent == nil {
+		t.root = y
+	} else if x == x.parent.left {
+		x.parent.left = y
+	} else {
+		x.parent.right = y
+	}
+
+	y.left = x
+	x.parent = y
+
+	x.height = 1 + maxInt8(nodeHeight(x.left), nodeHeight(x.right))
+	y.height = 1 + maxInt8(nodeHeight(y.left), nodeHeight(y.right))
+	if t.augment != nil {
+		// x is now y's child, so it must be refreshed first.
+		t.augment(x)
+		t.augment(y)
+	}
+	return y
+}
+
+// rotateRight performs a right rotation around x, promoting x.left to take
+// x's place, and returns the new subtree root.
+func (t *Tree) rotateRight(x *Node) *Node {
+	y := x.left
+	x.left = y.right
+	if y.right != nil {
+		y.right.parent = x
+	}
+
+	y.parent = x.parent
+	if x.parent == nil {
+		t.root = y
+	} else if x == x.parent.left {
+		x.parent.left = y
+	} else {
+		x.parent.right = y
+	}
+
+	y.right = x
+	x.parent = y
+
+	x.height = 1 + maxInt8(nodeHeight(x.left), nodeHeight(x.right))
+	y.height = 1 + maxInt8(nodeHeight(y.left), nodeHeight(y.right))
+	if t.augment != nil {
+		// x is now y's child, so it must be refreshed first.
+		t.augment(x)
+		t.augment(y)
+	}
+	return y
+}
+
+// LevelOrder returns the tree's values grouped by depth, with the root's
+// level at index 0. Unlike String, which only shows the sorted order, this
+// makes it obvious when a tree has degenerated into a stick: a tree with a
+// log2(n)-ish number of levels is healthy, one with n of them is not.
+func (t *Tree) LevelOrder() [][]Value {
+	var levels [][]Value
+	if t.root == nil {
+		return levels
+	}
+
+	queue := []*Node{t.root}
+	for len(queue) > 0 {
+		level := make([]Value, len(queue))
+		var next []*Node
+		for i, n := range queue {
+			level[i] = n.val
+			if n.left != nil {
+				next = append(next, n.left)
+			}
+			if n.right != nil {
+				next = append(next, n.right)
+			}
+		}
+		levels = append(levels, level)
+		queue = next
+	}
+	return levels
+}
+
+// LevelOrderBottom is LevelOrder with the levels reversed, so the leaves
+// come first and the root comes last.
+func (t *Tree) LevelOrderBottom() [][]Value {
+	levels := t.LevelOrder()
+	for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+		levels[i], levels[j] = levels[j], levels[i]
+	}
+	return levels
+}
+
+// Dot writes the tree to w as a Graphviz DOT graph, with one edge per
+// parent-child link and each node labeled with its value.
+func (t *Tree) Dot(w io.Writer) {
+	fmt.Fprintln(w, "digraph Tree {")
+	if t.root != nil {
+		id := map[*Node]int{t.root: 0}
+		queue := []*Node{t.root}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+
+			fmt.Fprintf(w, "\tn%d [label=\"%v\"];\n", id[n], n.val)
+			for _, c := range [2]*Node{n.left, n.right} {
+				if c == nil {
+					continue
+				}
+				id[c] = len(id)
+				fmt.Fprintf(w, "\tn%d -> n%d;\n", id[n], id[c])
+				queue = append(queue, c)
+			}
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// PrettyPrint writes an ASCII rendering of the tree's structure to w, using
+// box-drawing characters to connect each node to its children, e.g.:
+//
+//	5
+//	├── 2
+//	│   ├── 1
+//	│   └── 3
+//	└── 7
+//	    └── 9
+func (t *Tree) PrettyPrint(w io.Writer) {
+	if t.root == nil {
+		fmt.Fprintln(w, "(empty)")
+		return
+	}
+	fmt.Fprintf(w, "%v\n", t.root.val)
+
+	type frame struct {
+		n      *Node
+		prefix string
+		last   bool
+	}
+
+	// A stack keeps this iterative instead of recursive, so a degenerate,
+	// stick-shaped tree can't blow it up with deep recursion.
+	var stack []frame
+	push := func(n *Node, prefix string, last bool) {
+		if n != nil {
+			stack = append(stack, frame{n, prefix, last})
+		}
+	}
+	// Pushed in this order so the left child, once present, is popped
+	// (and so printed) before the right child.
+	push(t.root.right, "", true)
+	push(t.root.left, "", t.root.right == nil)
+
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		branch, childPrefix := "├── ", f.prefix+"│   "
+		if f.last {
+			branch, childPrefix = "└── ", f.prefix+"    "
+		}
+		fmt.Fprintf(w, "%s%s%v\n", f.prefix, branch, f.n.val)
+
+		push(f.n.right, childPrefix, true)
+		push(f.n.left, childPrefix, f.n.right == nil)
+	}
+}
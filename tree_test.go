@@ -7,6 +7,9 @@
 package tree
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -26,23 +29,30 @@ type test struct {
 	missing []Value
 	remOrd  []Value
 
-	// height depends on the insertion order.
+	// height depends on the insertion order; these describe the plain,
+	// unbalanced BST built by NewUnbalanced.
 	root   Value
 	height int
+
+	// heightBalanced is the maximum height New's AVL rebalancing may ever
+	// produce for a tree holding siz elements, regardless of insertion
+	// order. It doesn't depend on insertion order at all.
+	heightBalanced int
 }
 
 var (
 	t1 = test{
-		vs:      []Value{5, 2, 7, 3, 1, 6, 9, 4, 8, 2, 1, 8},
-		siz:     9,
-		max:     1,
-		min:     9,
-		str:     "[9 8 7 6 5 4 3 2 1]",
-		sorted:  []Value{9, 8, 7, 6, 5, 4, 3, 2, 1},
-		missing: []Value{0, 10, -5, 45, 347, -1},
-		remOrd:  []Value{6, 8, 3, 1, 5},
-		root:    5,
-		height:  4,
+		vs:             []Value{5, 2, 7, 3, 1, 6, 9, 4, 8, 2, 1, 8},
+		siz:            9,
+		max:            1,
+		min:            9,
+		str:            "[9 8 7 6 5 4 3 2 1]",
+		sorted:         []Value{9, 8, 7, 6, 5, 4, 3, 2, 1},
+		missing:        []Value{0, 10, -5, 45, 347, -1},
+		remOrd:         []Value{6, 8, 3, 1, 5},
+		root:           5,
+		height:         4,
+		heightBalanced: 4,
 		fn: func(a, b Value) bool {
 			ai := a.(int)
 			bi := b.(int)
@@ -51,16 +61,17 @@ var (
 	}
 
 	t2 = test{
-		vs:      []Value{1, 2, 3, 4, 5, 6, 7, 8, 9},
-		siz:     9,
-		max:     9,
-		min:     1,
-		str:     "[1 2 3 4 5 6 7 8 9]",
-		sorted:  []Value{1, 2, 3, 4, 5, 6, 7, 8, 9},
-		missing: []Value{0, 10, -5, 45, 347, -1},
-		remOrd:  []Value{2, 4, 1, 3},
-		root:    1,
-		height:  9,
+		vs:             []Value{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		siz:            9,
+		max:            9,
+		min:            1,
+		str:            "[1 2 3 4 5 6 7 8 9]",
+		sorted:         []Value{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		missing:        []Value{0, 10, -5, 45, 347, -1},
+		remOrd:         []Value{2, 4, 1, 3},
+		root:           1,
+		height:         9,
+		heightBalanced: 4,
 		fn: func(a, b Value) bool {
 			ai := a.(int)
 			bi := b.(int)
@@ -69,16 +80,17 @@ var (
 	}
 
 	t3 = test{
-		vs:      []Value{67.9, -1.5, 4e8, 567.34, -567.89, 0.0, 0.0},
-		siz:     6,
-		max:     4e8,
-		min:     -567.89,
-		str:     "[-567.89 -1.5 0 67.9 567.34 4e+08]",
-		sorted:  []Value{-567.89, -1.5, 0.0, 67.9, 567.34, 4.0e8},
-		missing: []Value{67.99, 0.00001, -1.6, 1.0, 400.0},
-		remOrd:  []Value{67.9, 0.0},
-		root:    67.9,
-		height:  3,
+		vs:             []Value{67.9, -1.5, 4e8, 567.34, -567.89, 0.0, 0.0},
+		siz:            6,
+		max:            4e8,
+		min:            -567.89,
+		str:            "[-567.89 -1.5 0 67.9 567.34 4e+08]",
+		sorted:         []Value{-567.89, -1.5, 0.0, 67.9, 567.34, 4.0e8},
+		missing:        []Value{67.99, 0.00001, -1.6, 1.0, 400.0},
+		remOrd:         []Value{67.9, 0.0},
+		root:           67.9,
+		height:         3,
+		heightBalanced: 3,
 		fn: func(a, b Value) bool {
 			af := a.(float64)
 			bf := b.(float64)
@@ -87,16 +99,17 @@ var (
 	}
 
 	t4 = test{
-		vs:      []Value{"Lisa", "Lukas", "Ben", "Chris", "Chris", "Benni", "Sara", "Patrick"},
-		siz:     7,
-		max:     "Sara",
-		min:     "Ben",
-		str:     "[Ben Benni Chris Lisa Lukas Patrick Sara]",
-		sorted:  []Value{"Ben", "Benni", "Chris", "Lisa", "Lukas", "Patrick", "Sara"},
-		missing: []Value{"Dan", "Benjamin", "Christopher", "Marietta", "Wolfgang", "Ruth"},
-		remOrd:  []Value{"Patrick", "Sara", "Lisa", "Lukas"},
-		root:    "Lisa",
-		height:  4,
+		vs:             []Value{"Lisa", "Lukas", "Ben", "Chris", "Chris", "Benni", "Sara", "Patrick"},
+		siz:            7,
+		max:            "Sara",
+		min:            "Ben",
+		str:            "[Ben Benni Chris Lisa Lukas Patrick Sara]",
+		sorted:         []Value{"Ben", "Benni", "Chris", "Lisa", "Lukas", "Patrick", "Sara"},
+		missing:        []Value{"Dan", "Benjamin", "Christopher", "Marietta", "Wolfgang", "Ruth"},
+		remOrd:         []Value{"Patrick", "Sara", "Lisa", "Lukas"},
+		root:           "Lisa",
+		height:         4,
+		heightBalanced: 4,
 		fn: func(a, b Value) bool {
 			as := a.(string)
 			bs := b.(string)
@@ -113,7 +126,7 @@ func TestTree(o *testing.T) {
 			return !want.fn(a, b) && !want.fn(b, a)
 		}
 
-		tree := New(want.fn)
+		tree := NewUnbalanced(want.fn)
 		tree.Init(want.vs)
 
 		if siz := tree.Len(); siz != want.siz {
@@ -150,6 +163,37 @@ func TestTree(o *testing.T) {
 	}
 }
 
+// TestBalancedTree feeds the same, possibly adversarial, insertion order as
+// TestTree into a self-balancing tree (New) and checks that the AVL
+// rotations keep it within the bound promised by New, even for t2, whose
+// sorted input degenerates NewUnbalanced into a stick of height 9.
+func TestBalancedTree(o *testing.T) {
+	for _, want := range tests {
+		tree := New(want.fn)
+		tree.Init(want.vs)
+
+		if siz := tree.Len(); siz != want.siz {
+			o.Errorf("tree.Len() = %v; want %v", siz, want.siz)
+		}
+		if out := tree.Slice(); !sliceEquals(out, want.sorted) {
+			o.Errorf("tree.Slice() = %v; want %v", out, want.sorted)
+		}
+		if height := tree.Height(); height > want.heightBalanced {
+			o.Errorf("tree.Height() = %v; want <= %v", height, want.heightBalanced)
+		}
+		for _, v := range want.sorted {
+			if !tree.Contains(v) {
+				o.Errorf("tree.Contains(%v) = false; want true", v)
+			}
+		}
+		for _, v := range want.missing {
+			if tree.Contains(v) {
+				o.Errorf("tree.Contains(%v) = true; want false", v)
+			}
+		}
+	}
+}
+
 func TestRandTree(o *testing.T) {
 	for _, want := range tests {
 		equals := func(a, b Value) bool {
@@ -279,6 +323,139 @@ func TestNextPrev(o *testing.T) {
 	}
 }
 
+func TestRange(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	if out := tree.Range(3, 7); !sliceEquals(out, []Value{3, 4, 5, 6, 7}) {
+		o.Errorf("tree.Range(3, 7) = %v; want [3 4 5 6 7]", out)
+	}
+	if out := tree.Range(10, 20); !sliceEquals(out, []Value{}) {
+		o.Errorf("tree.Range(10, 20) = %v; want []", out)
+	}
+}
+
+func TestAscendDescend(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	collect := func(walk func(fn func(Value) bool)) []Value {
+		var out []Value
+		walk(func(v Value) bool {
+			out = append(out, v)
+			return true
+		})
+		return out
+	}
+
+	if out := collect(func(fn func(Value) bool) { tree.AscendGreaterOrEqual(6, fn) }); !sliceEquals(out, []Value{6, 7, 8, 9}) {
+		o.Errorf("tree.AscendGreaterOrEqual(6) = %v; want [6 7 8 9]", out)
+	}
+	if out := collect(func(fn func(Value) bool) { tree.DescendLessOrEqual(4, fn) }); !sliceEquals(out, []Value{4, 3, 2, 1}) {
+		o.Errorf("tree.DescendLessOrEqual(4) = %v; want [4 3 2 1]", out)
+	}
+	if out := collect(func(fn func(Value) bool) { tree.AscendRange(3, 7, fn) }); !sliceEquals(out, []Value{3, 4, 5, 6, 7}) {
+		o.Errorf("tree.AscendRange(3, 7) = %v; want [3 4 5 6 7]", out)
+	}
+	if out := collect(func(fn func(Value) bool) { tree.DescendRange(3, 7, fn) }); !sliceEquals(out, []Value{7, 6, 5, 4, 3}) {
+		o.Errorf("tree.DescendRange(3, 7) = %v; want [7 6 5 4 3]", out)
+	}
+
+	// Early termination: fn returning false must stop the walk.
+	var seen []Value
+	tree.AscendGreaterOrEqual(1, func(v Value) bool {
+		seen = append(seen, v)
+		return v.(int) < 3
+	})
+	if !sliceEquals(seen, []Value{1, 2, 3}) {
+		o.Errorf("AscendGreaterOrEqual early stop = %v; want [1 2 3]", seen)
+	}
+}
+
+func TestAscendFrom(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	var out []Value
+	tree.Find(4).AscendFrom(func(v Value) bool {
+		out = append(out, v)
+		return true
+	})
+	if want := []Value{4, 5, 6, 7, 8, 9}; !sliceEquals(out, want) {
+		o.Errorf("Node.AscendFrom(4) = %v; want %v", out, want)
+	}
+}
+
+func TestLevelOrder(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	levels := tree.LevelOrder()
+	if got := len(levels); got == 0 || got > 4 {
+		o.Errorf("len(tree.LevelOrder()) = %v; want 1..4", got)
+	}
+
+	var flat []Value
+	for _, level := range levels {
+		flat = append(flat, level...)
+	}
+	if siz := len(flat); siz != tree.Len() {
+		o.Errorf("LevelOrder visited %v values; want %v", siz, tree.Len())
+	}
+
+	bottom := tree.LevelOrderBottom()
+	n := len(levels)
+	for i := range levels {
+		if !sliceEquals(levels[i], bottom[n-1-i]) {
+			o.Errorf("LevelOrderBottom()[%v] = %v; want LevelOrder()[%v] = %v", n-1-i, bottom[n-1-i], i, levels[i])
+		}
+	}
+
+	if empty := New(intLess).LevelOrder(); empty != nil {
+		o.Errorf("empty tree.LevelOrder() = %v; want nil", empty)
+	}
+}
+
+func TestDot(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7})
+
+	var buf bytes.Buffer
+	tree.Dot(&buf)
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "digraph Tree {") || !strings.HasSuffix(out, "}\n") {
+		o.Errorf("tree.Dot() = %q; want a digraph Tree {...} block", out)
+	}
+	for _, v := range []Value{5, 2, 7} {
+		if want := fmt.Sprintf("label=\"%v\"", v); !strings.Contains(out, want) {
+			o.Errorf("tree.Dot() = %q; want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestPrettyPrint(o *testing.T) {
+	tree := New(intLess)
+	tree.RandInit([]Value{5, 2, 7, 3, 1, 6, 9, 4, 8})
+
+	var buf bytes.Buffer
+	tree.PrettyPrint(&buf)
+	out := buf.String()
+
+	if got := strings.Count(out, "\n"); got != tree.Len() {
+		o.Errorf("tree.PrettyPrint() produced %v lines; want %v", got, tree.Len())
+	}
+	if !strings.Contains(out, "├── ") && !strings.Contains(out, "└── ") {
+		o.Errorf("tree.PrettyPrint() = %q; want box-drawing branches", out)
+	}
+
+	buf.Reset()
+	New(intLess).PrettyPrint(&buf)
+	if out := buf.String(); out != "(empty)\n" {
+		o.Errorf("empty tree.PrettyPrint() = %q; want \"(empty)\\n\"", out)
+	}
+}
+
 // sliceEquals returns true if two slices are equal.
 func sliceEquals(a, b []Value) bool {
 	n := len(a)